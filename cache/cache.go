@@ -0,0 +1,207 @@
+// Package cache persists downloaded tiles and composed wallpapers to disk,
+// mirroring the approach in adyxax/himawari: a partial run can resume
+// instead of redownloading everything, and the last wallpaper applied can be
+// restored on startup before the first network fetch completes.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dir returns the cache directory, creating it if necessary. It honors
+// XDG_CACHE_HOME, falling back to os.UserCacheDir.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	dir := filepath.Join(base, "earth-wallpaper")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// TilePath returns the on-disk path for a single downloaded tile, keyed by
+// resolution, the image timestamp, and its grid position, creating the
+// parent directory if necessary.
+func TilePath(resolution int, t time.Time, i, j int) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	tilesDir := filepath.Join(dir, "tiles")
+	if err := os.MkdirAll(tilesDir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%d_%04d%02d%02d_%02d%02d%02d_%d_%d.png",
+		resolution, t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), i, j)
+	return filepath.Join(tilesDir, name), nil
+}
+
+// WallpaperPath returns the on-disk path for a fully composed wallpaper,
+// keyed by its source label and timestamp, creating the parent directory if
+// necessary. The label is part of the key because different sources can
+// produce the same timestamp (e.g. APOD always resolves to midnight, and
+// Himawari naturally produces a midnight frame too), and without it one
+// source's composite would be served back under another source's name.
+func WallpaperPath(source string, t time.Time) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	wallpapersDir := filepath.Join(dir, "wallpapers")
+	if err := os.MkdirAll(wallpapersDir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s_%04d%02d%02d_%02d%02d%02d.png",
+		sanitizeLabel(source), t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+	return filepath.Join(wallpapersDir, name), nil
+}
+
+// LoadWallpaper reports the cached path for a previously composed wallpaper
+// from source with this timestamp, if one exists.
+func LoadWallpaper(source string, t time.Time) (string, bool) {
+	path, err := WallpaperPath(source, t)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// sanitizeLabel makes a Downloader's Label() safe to use as a filename
+// component.
+func sanitizeLabel(label string) string {
+	var b strings.Builder
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// State records the last wallpaper successfully applied, so it can be
+// reapplied immediately on startup, before the first network fetch.
+type State struct {
+	LastApplied string `json:"last_applied"` // RFC3339 timestamp of the applied image
+	LastPath    string `json:"last_path"`    // cached PNG path for that image
+	Satellite   string `json:"satellite"`    // source label the image came from
+	Resolution  string `json:"resolution"`   // "WxH" pixel dimensions of the composed image
+}
+
+func statePath(dir string) string {
+	return filepath.Join(dir, "data.json")
+}
+
+// LoadState reads the persisted State. A missing file is not an error; it
+// returns the zero State.
+func LoadState() (State, error) {
+	dir, err := Dir()
+	if err != nil {
+		return State{}, err
+	}
+
+	data, err := os.ReadFile(statePath(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return State{}, nil
+		}
+		return State{}, err
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}, err
+	}
+	return s, nil
+}
+
+// SaveState persists the last-applied wallpaper so it survives a restart.
+func SaveState(s State) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath(dir), data, 0o644)
+}
+
+// Evict removes cached tiles and wallpapers whose last modification is older
+// than ttl. The state file, and the wallpaper it points at, are left
+// untouched so the last-applied wallpaper can still be restored even once its
+// own cache entry has expired.
+func Evict(ttl time.Duration) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	state, _ := LoadState()
+	cutoff := time.Now().Add(-ttl)
+	for _, sub := range []string{"tiles", "wallpapers"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			path := filepath.Join(dir, sub, e.Name())
+			if path == state.LastPath {
+				continue
+			}
+			_ = os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// Clear removes every cached tile, wallpaper, and the persisted state, used
+// by the "Clear cache" systray item.
+func Clear() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package config loads and persists user-adjustable runtime settings, such
+// as the daylight offset and timelapse mode, to a JSON file in the user's
+// config directory so they survive a restart.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Config holds the settings exposed through the systray menus.
+type Config struct {
+	// DaylightOffsetHours shifts the time used to request imagery, modeled
+	// on the `delay` constant in the adyxax/himawari project, so users
+	// outside the satellite's home timezone can see their own local
+	// daylight on the globe. E.g. -8 requests the image as it looked 8
+	// hours ago.
+	DaylightOffsetHours int `json:"daylight_offset_hours"`
+
+	// Timelapse controls the timelapse playback mode.
+	Timelapse TimelapseConfig `json:"timelapse"`
+
+	// Hook controls the optional post-set hook script.
+	Hook HookConfig `json:"hook"`
+
+	// TileWorkers bounds how many satellite tiles are downloaded
+	// concurrently. Zero uses runtime.NumCPU().
+	TileWorkers int `json:"tile_workers"`
+}
+
+// TimelapseConfig controls timelapse playback: instead of always fetching
+// the latest image, the fetcher steps through the last WindowHours of
+// imagery, one frame every StepMinutes, applying a new frame every
+// FrameSeconds.
+type TimelapseConfig struct {
+	Enabled      bool `json:"enabled"`
+	WindowHours  int  `json:"window_hours"`
+	StepMinutes  int  `json:"step_minutes"`
+	FrameSeconds int  `json:"frame_seconds"`
+}
+
+// HookConfig controls the post-set hook script run after each wallpaper
+// change (see wallpaper.HookConfig). Command is run as-is via "sh -c", so it
+// can be a single binary or a small pipeline.
+type HookConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Command        string `json:"command"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Default returns the settings used the first time the app runs.
+func Default() Config {
+	return Config{
+		DaylightOffsetHours: 0,
+		Timelapse: TimelapseConfig{
+			Enabled:      false,
+			WindowHours:  6,
+			StepMinutes:  10,
+			FrameSeconds: 5,
+		},
+		Hook: HookConfig{
+			Enabled:        false,
+			Command:        "",
+			TimeoutSeconds: 10,
+		},
+		TileWorkers: 0,
+	}
+}
+
+func path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "earth-wallpaper")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load reads the persisted Config, falling back to Default when none exists
+// yet.
+func Load() (Config, error) {
+	p, err := path()
+	if err != nil {
+		return Default(), err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return Default(), err
+	}
+
+	cfg := Default()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), err
+	}
+	return cfg, nil
+}
+
+// Save persists cfg so it's picked up again on the next startup.
+func Save(cfg Config) error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
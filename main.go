@@ -1,24 +1,24 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"earth-wallpaper/cache"
+	"earth-wallpaper/config"
 	"earth-wallpaper/icon"
 	"earth-wallpaper/wallpaper"
 	"earth-wallpaper/wallpaper/modes"
+	"earth-wallpaper/wallpaper/source"
 
 	"fyne.io/systray"
 )
@@ -30,116 +30,193 @@ var (
 	latestImageMu   sync.RWMutex
 )
 
-const resolution int = 4
-const tileSize = 550
-const border = 180 // pixels of black padding on each side; adjust as needed
+// activeSource is the Downloader currently polled by startFetcher. Protected
+// by activeSourceMu so the systray source menu can swap it at runtime.
+var (
+	activeSource   source.Downloader = &source.Himawari{Resolution: resolution}
+	activeSourceMu sync.RWMutex
+)
+
+// appConfig holds the persisted daylight-offset and timelapse settings.
+// Protected by appConfigMu so the systray menus can update it at runtime.
+var (
+	appConfig   = config.Default()
+	appConfigMu sync.RWMutex
+)
+
+// restartActiveMode, when set, stops and restarts the currently running
+// fetch mode (normal polling vs. timelapse). It's wired up once onReady has
+// started the Latest Image section, and used by the Timelapse menu so
+// flipping it takes effect immediately instead of waiting for the next
+// toggle of "Latest Image". Protected by restartActiveModeMu since it's
+// written from the goroutine onReady starts and read from the Timelapse
+// menu's own, separately running click handler.
+var (
+	restartActiveMode   func()
+	restartActiveModeMu sync.RWMutex
+)
 
-// tileResult holds the result of a downloaded tile image
-type tileResult struct {
-	x, y int
-	img  image.Image
+// setRestartActiveMode installs the function the Timelapse menu calls to
+// restart the active fetch mode immediately.
+func setRestartActiveMode(fn func()) {
+	restartActiveModeMu.Lock()
+	restartActiveMode = fn
+	restartActiveModeMu.Unlock()
 }
 
-func downloadImage(resolution, i, j int, t time.Time) image.Image {
-	var year, month, day, hour, minute, second string
+// getRestartActiveMode returns the currently installed restart function, or
+// nil if onReady hasn't started the Latest Image section yet.
+func getRestartActiveMode() func() {
+	restartActiveModeMu.RLock()
+	defer restartActiveModeMu.RUnlock()
+	return restartActiveMode
+}
 
-	if t.IsZero() {
-		// fallback
-		log.Printf("downloadImage: received zero time, using fallback date")
-		year, month, day = "2026", "01", "10"
-		hour, minute, second = "02", "00", "00"
-	} else {
-		year = fmt.Sprintf("%04d", t.Year())
-		month = fmt.Sprintf("%02d", t.Month())
-		day = fmt.Sprintf("%02d", t.Day())
-		hour = fmt.Sprintf("%02d", t.Hour())
-		minute = fmt.Sprintf("%02d", t.Minute())
-		second = fmt.Sprintf("%02d", t.Second())
-	}
+const resolution int = 4
+const border = 180 // pixels of black padding on each side; adjust as needed
 
-	timeStr := fmt.Sprintf("%s%s%s", hour, minute, second)
-	url := fmt.Sprintf("https://anzu.shinshu-u.ac.jp/himawari/img/D531106/%dd/550/%s/%s/%s/%s_%d_%d.png", resolution, year, month, day, timeStr, i, j)
-	resp, err := http.Get(url)
-	if err != nil {
-		log.Printf("downloadImage: http get error for %s: %v", url, err)
-		// return a blank placeholder tile so the final image stays complete
-		return image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-	}
-	defer resp.Body.Close()
+// cacheTTL is how long a cached tile or composed wallpaper is kept before
+// Evict is allowed to remove it.
+const cacheTTL = 7 * 24 * time.Hour
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("downloadImage: non-200 status %d for %s", resp.StatusCode, url)
-		return image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-	}
+func getActiveSource() source.Downloader {
+	activeSourceMu.RLock()
+	defer activeSourceMu.RUnlock()
+	return activeSource
+}
 
-	var buf bytes.Buffer
-	_, err = io.Copy(&buf, resp.Body)
-	if err != nil {
-		log.Printf("downloadImage: read body error for %s: %v", url, err)
-		return image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
-	}
+func getConfig() config.Config {
+	appConfigMu.RLock()
+	defer appConfigMu.RUnlock()
+	return appConfig
+}
 
-	img, err := png.Decode(&buf)
-	if err != nil {
-		log.Printf("downloadImage: png decode error for %s: %v", url, err)
-		return image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+// updateConfig applies fn to a copy of the current config, persists the
+// result, and stores it as the new appConfig.
+func updateConfig(fn func(cfg *config.Config)) {
+	appConfigMu.Lock()
+	cfg := appConfig
+	fn(&cfg)
+	appConfig = cfg
+	appConfigMu.Unlock()
+
+	if err := config.Save(cfg); err != nil {
+		log.Printf("updateConfig: failed to persist config: %v", err)
 	}
-	return img
 }
 
-func setWallpaper(fullImagePath string) {
+// setActiveSource switches the polled source and resets latestImageTime so
+// the next fetch picks up the new source's latest image immediately instead
+// of waiting for it to look "newer" than whatever the old source had.
+func setActiveSource(d source.Downloader) {
+	activeSourceMu.Lock()
+	activeSource = d
+	activeSourceMu.Unlock()
+
+	latestImageMu.Lock()
+	latestImageTime = time.Time{}
+	latestImageDate = ""
+	latestImageMu.Unlock()
+}
+
+func setWallpaper(fullImagePath string, meta wallpaper.HookMeta) {
 	// set wallpaper mode first, then apply the wallpaper
 	wallpaper.SetMode(modes.FILL_ORIGINAL)
-	err := wallpaper.SetWallpaper(fullImagePath)
+	err := wallpaper.SetWallpaperWithMeta(fullImagePath, meta)
 	if err != nil {
 		log.Printf("setWallpaper error: %v", err)
 		return
 	}
 }
 
-// startFetcher runs a loop to fetch latest image info immediately and then every 10s.
-func startFetcher(stopCh chan bool, mLatestImageDate *systray.MenuItem) {
+// defaultTooltip is restored once a wallpaper composes without errors.
+const defaultTooltip = "Live wallpaper from Himawari 8 satellite"
+
+// updateTooltip surfaces a partial-download error (e.g. "3/16 tiles
+// failed") in the systray tooltip instead of letting it disappear into the
+// log, and restores the default tooltip once a run succeeds cleanly.
+func updateTooltip(err error) {
+	if err != nil {
+		systray.SetTooltip(fmt.Sprintf("Earth Wallpaper: %s", err.Error()))
+		return
+	}
+	systray.SetTooltip(defaultTooltip)
+}
+
+// applyHookConfig pushes the persisted hook settings down into the
+// wallpaper package, which actually runs the hook after each SetWallpaper.
+func applyHookConfig(cfg config.Config) {
+	wallpaper.SetHookConfig(wallpaper.HookConfig{
+		Enabled: cfg.Hook.Enabled,
+		Command: cfg.Hook.Command,
+		Timeout: time.Duration(cfg.Hook.TimeoutSeconds) * time.Second,
+	})
+}
+
+// startFetcher runs a loop to fetch the active source's latest image info
+// immediately and then every 10s.
+func startFetcher(ctx context.Context, mLatestImageDate *systray.MenuItem) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
+	// This process runs indefinitely, writing new tiles/composites every
+	// cycle, so eviction needs to run periodically here too, not just once
+	// in onReady at startup, or disk usage grows without bound for the
+	// lifetime of the run.
+	evictTicker := time.NewTicker(cacheTTL / 4)
+	defer evictTicker.Stop()
+
 	doFetch := func() {
-		raw, err := latestImage()
+		d := getActiveSource()
+
+		latestImageMu.RLock()
+		since := latestImageTime
+		latestImageMu.RUnlock()
+
+		id, err := d.ModifiedSince(since)
 		if err != nil {
 			mLatestImageDate.SetTitle("Date: Error fetching")
-			log.Printf("startFetcher: latestImage error: %v", err)
+			log.Printf("startFetcher: %s ModifiedSince error: %v", d.Label(), err)
 			return
 		}
 
-		// Try to parse returned date
-		parsed, perr := time.Parse("2006-01-02 15:04:05", raw)
+		parsed, perr := source.Time(id)
 		if perr != nil {
-			// Keep raw string for display
-			latestImageMu.Lock()
-			latestImageDate = raw
-			latestImageMu.Unlock()
-			mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s", raw))
-			log.Printf("startFetcher: failed to parse date '%s': %v", raw, perr)
+			mLatestImageDate.SetTitle("Date: Error fetching")
+			log.Printf("startFetcher: failed to parse id %q from %s: %v", id, d.Label(), perr)
 			return
 		}
 
-		// Update stored time and process wallpaper only when newer
 		latestImageMu.Lock()
 		prev := latestImageTime
 		if prev.IsZero() || parsed.After(prev) {
 			latestImageTime = parsed
-			latestImageDate = raw
+			latestImageDate = parsed.Format("2006-01-02 15:04:05")
 			latestImageMu.Unlock()
-			mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s", raw))
-			// New image: compose wallpaper and set it
-			fullPath := processWallpaper(parsed)
+			mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s", latestImageDate))
+
+			// New image: compose wallpaper and set it, shifted by the
+			// configured daylight offset if any.
+			requestID := id
+			if offset := getConfig().DaylightOffsetHours; offset != 0 {
+				shifted, err := source.WithOffset(id, time.Duration(offset)*time.Hour)
+				if err != nil {
+					log.Printf("startFetcher: offset error: %v", err)
+				} else {
+					requestID = shifted
+				}
+			}
+
+			fullPath, meta, downloadErr := processWallpaper(ctx, d, requestID)
 			if fullPath != "" {
-				setWallpaper(fullPath)
+				setWallpaper(fullPath, meta)
 			}
+			updateTooltip(downloadErr)
 			return
 		}
 		// not newer
 		latestImageMu.Unlock()
-		mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s", raw))
+		mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s", latestImageDate))
 	}
 
 	// immediate first fetch
@@ -149,67 +226,163 @@ func startFetcher(stopCh chan bool, mLatestImageDate *systray.MenuItem) {
 		select {
 		case <-ticker.C:
 			doFetch()
-		case <-stopCh:
+		case <-evictTicker.C:
+			if err := cache.Evict(cacheTTL); err != nil {
+				log.Printf("startFetcher: cache eviction error: %v", err)
+			}
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func processWallpaper(t time.Time) string {
-	gridSize := resolution
-	// create a new blank canvas
-	canvas := image.NewRGBA(image.Rect(0, 0, gridSize*tileSize, gridSize*tileSize))
-
-	log.Printf("Start parallel download image")
-	start_time := time.Now()
-
-	results := make(chan tileResult, gridSize*gridSize)
-	var wg sync.WaitGroup
-
-	for i := 0; i < gridSize; i++ {
-		for j := 0; j < gridSize; j++ {
-			wg.Add(1)
-			go func(x, y int) {
-				defer wg.Done()
-				img := downloadImage(resolution, x, y, t)
-				results <- tileResult{x: x, y: y, img: img}
-			}(i, j)
+// startTimelapse plays the configured window ending at the source's latest
+// image, composing and setting each frame in turn from the oldest to the
+// latest so the earth appears to animate on the desktop. It returns false
+// when ctx is cancelled, and true otherwise: after walking the whole window,
+// or straight away when the latest image id can't be fetched.
+func startTimelapse(ctx context.Context, d source.Downloader, mLatestImageDate *systray.MenuItem, cfg config.TimelapseConfig) bool {
+	latestID, err := d.ModifiedSince(time.Time{})
+	if err != nil {
+		log.Printf("startTimelapse: %s ModifiedSince error: %v", d.Label(), err)
+		return true
+	}
+
+	stepMinutes := cfg.StepMinutes
+	if stepMinutes <= 0 {
+		stepMinutes = 10
+	}
+	frameInterval := time.Duration(cfg.FrameSeconds) * time.Second
+	if frameInterval <= 0 {
+		frameInterval = 5 * time.Second
+	}
+	steps := cfg.WindowHours * 60 / stepMinutes
+	if steps < 1 {
+		steps = 1
+	}
+
+	for step := steps; step >= 0; step-- {
+		offset := -time.Duration(step*stepMinutes) * time.Minute
+		frameID, err := source.WithOffset(latestID, offset)
+		if err != nil {
+			log.Printf("startTimelapse: offset error: %v", err)
+			continue
+		}
+
+		if frameTime, err := source.Time(frameID); err == nil {
+			mLatestImageDate.SetTitle(fmt.Sprintf("Date: %s (timelapse)", frameTime.Format("2006-01-02 15:04:05")))
+		}
+
+		fullPath, meta, downloadErr := processWallpaper(ctx, d, frameID)
+		if fullPath != "" {
+			setWallpaper(fullPath, meta)
+		}
+		updateTooltip(downloadErr)
+
+		select {
+		case <-time.After(frameInterval):
+		case <-ctx.Done():
+			return false
 		}
 	}
+	return true
+}
 
+// runActiveMode runs whichever fetch mode is currently configured: normal
+// latest-image polling, or a looping timelapse through the configured
+// window. It derives a context from stopCh so that toggling fetching off
+// cancels an in-flight download (tile requests and retry backoff included)
+// instead of letting it run to completion. It returns once stopCh fires.
+func runActiveMode(stopCh chan bool, mLatestImageDate *systray.MenuItem) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	go func() {
-		wg.Wait()
-		close(results)
+		<-stopCh
+		cancel()
 	}()
 
-	count := 0
-	for res := range results {
-		dest := image.Rect(res.x*tileSize, res.y*tileSize, (res.x+1)*tileSize, (res.y+1)*tileSize)
-		draw.Draw(canvas, dest, res.img, image.Point{0, 0}, draw.Src)
-		count++
+	cfg := getConfig()
+	if !cfg.Timelapse.Enabled {
+		startFetcher(ctx, mLatestImageDate)
+		return
+	}
+
+	d := getActiveSource()
+	for {
+		if !startTimelapse(ctx, d, mLatestImageDate, getConfig().Timelapse) {
+			return
+		}
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processWallpaper downloads and composes the wallpaper for id, returning
+// its path, the metadata the post-set hook should see, and a non-nil error
+// when the download only partially succeeded (e.g. some tiles failed every
+// retry). A partial error still comes with a usable path: the caller should
+// apply the wallpaper and surface the error, not discard the result.
+func processWallpaper(ctx context.Context, d source.Downloader, id string) (string, wallpaper.HookMeta, error) {
+	t, timeErr := source.Time(id)
+	meta := wallpaper.HookMeta{Satellite: d.Label()}
+	if timeErr == nil {
+		meta.Timestamp = t
+	}
+
+	if timeErr == nil {
+		if cached, ok := cache.LoadWallpaper(d.Label(), t); ok {
+			log.Printf("processWallpaper: using cached wallpaper for %s", t.Format(time.RFC3339))
+			if w, h, err := imageDimensions(cached); err == nil {
+				meta.Resolution = fmt.Sprintf("%dx%d", w, h)
+			}
+			saveLastApplied(meta, cached)
+			return cached, meta, nil
+		}
+	}
+
+	log.Printf("Start downloading image from %s", d.Label())
+	startTime := time.Now()
+
+	img, downloadErr := d.Download(ctx, id)
+	if img == nil {
+		log.Printf("processWallpaper: %s download error: %v", d.Label(), downloadErr)
+		return "", meta, downloadErr
+	}
+	if downloadErr != nil {
+		log.Printf("processWallpaper: %s download completed with errors: %v", d.Label(), downloadErr)
 	}
 
-	log.Printf("End download image, processed %d tiles, took %d ms", count, time.Since(start_time).Milliseconds())
+	log.Printf("End download image, took %d ms", time.Since(startTime).Milliseconds())
 
 	// add a uniform black border to avoid distortion when displayed
-	srcW := canvas.Bounds().Dx()
-	srcH := canvas.Bounds().Dy()
+	srcW := img.Bounds().Dx()
+	srcH := img.Bounds().Dy()
 	dstW := srcW + border*2
 	dstH := srcH + border*2
 	bordered := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
 
 	// fill with black
 	draw.Draw(bordered, bordered.Bounds(), &image.Uniform{C: color.Black}, image.Point{}, draw.Src)
-	// draw original canvas centered with the border offset
-	draw.Draw(bordered, image.Rect(border, border, border+srcW, border+srcH), canvas, image.Point{0, 0}, draw.Src)
+	// draw original image centered with the border offset
+	draw.Draw(bordered, image.Rect(border, border, border+srcW, border+srcH), img, img.Bounds().Min, draw.Src)
+	meta.Resolution = fmt.Sprintf("%dx%d", dstW, dstH)
+
+	// prefer saving straight into the cache, keyed by the image's own
+	// timestamp, so it survives a restart; fall back to the system temp
+	// folder if the cache directory isn't available, or if some tiles
+	// failed, so a retry next run doesn't just reload the same gaps.
+	fullImagePath, cacheErr := cache.WallpaperPath(d.Label(), t)
+	if timeErr != nil || cacheErr != nil || downloadErr != nil {
+		fullImagePath = filepath.Join(os.TempDir(), "earth_wallpaper_full.png")
+	}
 
-	// save bordered image to system temp folder
-	tempDir := os.TempDir()
-	fullImagePath := filepath.Join(tempDir, "earth_wallpaper_full.png")
 	outFile, err := os.Create(fullImagePath)
 	if err != nil {
 		log.Printf("processWallpaper: failed to create file: %v", err)
-		return ""
+		return "", meta, downloadErr
 	}
 	defer outFile.Close()
 
@@ -219,7 +392,59 @@ func processWallpaper(t time.Time) string {
 	}
 
 	log.Printf("Wallpaper save to: %s", fullImagePath)
-	return fullImagePath
+	if timeErr == nil && downloadErr == nil {
+		saveLastApplied(meta, fullImagePath)
+	}
+	return fullImagePath, meta, downloadErr
+}
+
+// imageDimensions reads just enough of path to report its pixel dimensions.
+func imageDimensions(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// saveLastApplied records the wallpaper applied so it can be restored on the
+// next startup, before the first network fetch completes.
+func saveLastApplied(meta wallpaper.HookMeta, path string) {
+	err := cache.SaveState(cache.State{
+		LastApplied: meta.Timestamp.Format(time.RFC3339),
+		LastPath:    path,
+		Satellite:   meta.Satellite,
+		Resolution:  meta.Resolution,
+	})
+	if err != nil {
+		log.Printf("saveLastApplied: failed to persist cache state: %v", err)
+	}
+}
+
+// restoreLastWallpaper re-applies the last cached wallpaper immediately on
+// startup, so the desktop is never blank while the first fetch is in flight.
+func restoreLastWallpaper() {
+	state, err := cache.LoadState()
+	if err != nil || state.LastPath == "" {
+		return
+	}
+	if _, err := os.Stat(state.LastPath); err != nil {
+		return
+	}
+
+	meta := wallpaper.HookMeta{Satellite: state.Satellite, Resolution: state.Resolution}
+	if t, err := time.Parse(time.RFC3339, state.LastApplied); err == nil {
+		meta.Timestamp = t
+	}
+
+	log.Printf("restoreLastWallpaper: re-applying cached wallpaper from %s", state.LastApplied)
+	setWallpaper(state.LastPath, meta)
 }
 
 func addQuitItem() {
@@ -232,32 +457,197 @@ func addQuitItem() {
 	}()
 }
 
+// addCacheMenu adds the "Clear cache" item, which wipes every cached tile,
+// composed wallpaper, and the persisted last-applied state.
+func addCacheMenu() {
+	mClearCache := systray.AddMenuItem("Clear cache", "Delete all cached tiles and wallpapers")
+	go func() {
+		for range mClearCache.ClickedCh {
+			if err := cache.Clear(); err != nil {
+				log.Printf("addCacheMenu: failed to clear cache: %v", err)
+				continue
+			}
+			log.Printf("Cache cleared")
+		}
+	}()
+}
+
+// addOffsetMenu adds the "Daylight offset" submenu. Selecting an entry
+// shifts the time used to request imagery by that many hours, modeled on the
+// `delay` constant in the adyxax/himawari project. The change is applied,
+// without needing a restart, the next time the fetcher finds a newer image;
+// the wallpaper already shown is not recomposed.
+func addOffsetMenu() {
+	mOffset := systray.AddMenuItem("Daylight offset", "Shift the requested image time to match your local daylight")
+
+	offsets := []int{-12, -8, -4, 0, 4, 8, 12}
+	for _, hours := range offsets {
+		label := fmt.Sprintf("%+dh", hours)
+		if hours == 0 {
+			label = "None (0h)"
+		}
+		item := mOffset.AddSubMenuItem(label, fmt.Sprintf("Request imagery as it looked %+dh from now", hours))
+		go func(hours int, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				log.Printf("Setting daylight offset to %+dh", hours)
+				updateConfig(func(cfg *config.Config) { cfg.DaylightOffsetHours = hours })
+			}
+		}(hours, item)
+	}
+}
+
+// addTimelapseMenu adds the "Timelapse" toggle, which switches the fetcher
+// between normal latest-image polling and looping playback of the last
+// Timelapse.WindowHours of imagery.
+func addTimelapseMenu() {
+	mTimelapse := systray.AddMenuItem("Timelapse: Off", "Toggle timelapse playback mode")
+	if getConfig().Timelapse.Enabled {
+		mTimelapse.SetTitle("Timelapse: On")
+	}
+
+	go func() {
+		for range mTimelapse.ClickedCh {
+			var enabled bool
+			updateConfig(func(cfg *config.Config) {
+				cfg.Timelapse.Enabled = !cfg.Timelapse.Enabled
+				enabled = cfg.Timelapse.Enabled
+			})
+			if enabled {
+				mTimelapse.SetTitle("Timelapse: On")
+			} else {
+				mTimelapse.SetTitle("Timelapse: Off")
+			}
+			log.Printf("Timelapse mode enabled=%v", enabled)
+			if restart := getRestartActiveMode(); restart != nil {
+				restart()
+			}
+		}
+	}()
+}
+
+// addHookMenu adds the "Post-set hook" toggle. The command itself is
+// configured by hand in the config file; the menu only flips whether it
+// runs.
+func addHookMenu() {
+	mHook := systray.AddMenuItem("Post-set hook: Off", "Toggle running the configured post-set hook script")
+	if getConfig().Hook.Enabled {
+		mHook.SetTitle("Post-set hook: On")
+	}
+
+	go func() {
+		for range mHook.ClickedCh {
+			var cfg config.Config
+			updateConfig(func(c *config.Config) {
+				c.Hook.Enabled = !c.Hook.Enabled
+				cfg = *c
+			})
+			applyHookConfig(cfg)
+			if cfg.Hook.Enabled {
+				mHook.SetTitle("Post-set hook: On")
+			} else {
+				mHook.SetTitle("Post-set hook: Off")
+			}
+			log.Printf("Post-set hook enabled=%v", cfg.Hook.Enabled)
+		}
+	}()
+}
+
+// addSourceMenu adds the "Source" submenu used to switch which satellite/
+// imagery backend startFetcher polls, and wires each entry to setActiveSource.
+func addSourceMenu() {
+	mSource := systray.AddMenuItem("Source", "Choose the satellite image source")
+
+	type entry struct {
+		label string
+		build func() source.Downloader
+	}
+	entries := []entry{
+		{"Himawari 8", func() source.Downloader { return &source.Himawari{Resolution: resolution, Workers: getConfig().TileWorkers} }},
+		{"EPIC (DSCOVR)", func() source.Downloader { return &source.EPIC{} }},
+		{"NASA APOD", func() source.Downloader { return &source.APOD{} }},
+	}
+
+	for _, e := range entries {
+		item := mSource.AddSubMenuItem(e.label, fmt.Sprintf("Switch to %s", e.label))
+		go func(e entry, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				log.Printf("Switching source to %s", e.label)
+				setActiveSource(e.build())
+			}
+		}(e, item)
+	}
+}
+
 func onReady() {
 	systray.SetTemplateIcon(icon.Data, icon.Data)
 	systray.SetTitle("Earth Wallpaper")
-	systray.SetTooltip("Live wallpaper from Himawari 8 satellite")
+	systray.SetTooltip(defaultTooltip)
+	if cfg, err := config.Load(); err != nil {
+		log.Printf("onReady: failed to load config, using defaults: %v", err)
+	} else {
+		appConfigMu.Lock()
+		appConfig = cfg
+		appConfigMu.Unlock()
+	}
+	applyHookConfig(getConfig())
+	// activeSource is built before the config is loaded; rebuild it so the
+	// configured TileWorkers applies from the first fetch.
+	setActiveSource(&source.Himawari{Resolution: resolution, Workers: getConfig().TileWorkers})
+
 	addQuitItem()
 	systray.AddSeparator()
+	addSourceMenu()
+	addCacheMenu()
+	addOffsetMenu()
+	addTimelapseMenu()
+	addHookMenu()
+	systray.AddSeparator()
+
+	// Show whatever wallpaper was cached last run right away, so the desktop
+	// is never blank while the first fetch is still in flight.
+	restoreLastWallpaper()
+
+	if err := cache.Evict(cacheTTL); err != nil {
+		log.Printf("onReady: cache eviction error: %v", err)
+	}
 
 	// We can manipulate the systray in other goroutines
 	go func() {
 		systray.SetTemplateIcon(icon.Data, icon.Data)
 		systray.SetTitle("Earth Wallpaper")
-		systray.SetTooltip("Live wallpaper from Himawari 8 satellite")
+		systray.SetTooltip(defaultTooltip)
 		// Latest Image Section
 		mLatestImageStatus := systray.AddMenuItem("Latest Image: Running", "Click to toggle fetching")
 		mLatestImageDate := systray.AddMenuItem("Date: --", "Latest image date")
 
-		var stopCh chan bool
+		var activeModeMu sync.Mutex
+		stopCh := make(chan bool)
 		isRunning := true
 
-		// Initialize latest image fetching
-		stopCh = make(chan bool)
-		go startFetcher(stopCh, mLatestImageDate)
+		// Initialize latest image / timelapse fetching
+		go runActiveMode(stopCh, mLatestImageDate)
+
+		// restartActiveMode lets the Timelapse menu switch modes immediately
+		// instead of waiting for the next "Latest Image" toggle. It shares
+		// stopCh/isRunning with the toggle handler below, so both are
+		// guarded by activeModeMu: without it, concurrent clicks can race
+		// to replace stopCh, leaving one goroutine sending on a channel
+		// nobody reads anymore and blocking forever.
+		setRestartActiveMode(func() {
+			activeModeMu.Lock()
+			defer activeModeMu.Unlock()
+			if !isRunning {
+				return
+			}
+			stopCh <- true
+			stopCh = make(chan bool)
+			go runActiveMode(stopCh, mLatestImageDate)
+		})
 
 		// Toggle latest image fetching handler
 		go func() {
 			for range mLatestImageStatus.ClickedCh {
+				activeModeMu.Lock()
 				if isRunning {
 					isRunning = false
 					stopCh <- true
@@ -268,43 +658,14 @@ func onReady() {
 					stopCh = make(chan bool)
 					mLatestImageStatus.SetTitle("Latest Image: Running")
 
-					go startFetcher(stopCh, mLatestImageDate)
+					go runActiveMode(stopCh, mLatestImageDate)
 				}
+				activeModeMu.Unlock()
 			}
 		}()
 	}()
 }
 
-// LatestImageInfo represents the latest image information from the Himawari 8 satellite
-//
-// Example JSON response:
-//
-//	{
-//	  "date": "2026-01-11 16:10:00",
-//	  "file": "PI_H09_20260111_1610_TRC_FLDK_R10_PGPFD.png"
-//	}
-type LatestImageInfo struct {
-	Date string `json:"date"`
-	File string `json:"file"`
-}
-
-// latestImage fetches the latest image information from the Himawari 8 satellite
-func latestImage() (string, error) {
-	resp, err := http.Get("https://jh170034-1.kudpc.kyoto-u.ac.jp/himawari/img/D531106/latest.json")
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var data = LatestImageInfo{}
-	err = json.NewDecoder(resp.Body).Decode(&data)
-	if err != nil {
-		return "", err
-	}
-	log.Printf("Latest image date: %s", data.Date)
-	return data.Date, nil
-}
-
 func main() {
 	onExit := func() {
 		now := time.Now()
@@ -0,0 +1,74 @@
+package wallpaper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// HookMeta carries metadata about the wallpaper being set, exposed to the
+// post-set hook as environment variables.
+type HookMeta struct {
+	Satellite  string
+	Timestamp  time.Time
+	Resolution string
+}
+
+// HookConfig configures the optional post-set hook script, following the
+// pattern of wallhaven_dl's --script flag: the hook receives the wallpaper
+// path as $1 and can do whatever it likes with it (pywal, swaybg, dunst, a
+// lock-screen update, ...).
+type HookConfig struct {
+	Enabled bool
+	Command string        // run via "sh -c <Command> sh <file>"
+	Timeout time.Duration // zero means no timeout
+}
+
+var (
+	hookConfig   HookConfig
+	hookConfigMu sync.RWMutex
+)
+
+// SetHookConfig installs the post-set hook configuration used by subsequent
+// calls to SetWallpaperWithMeta.
+func SetHookConfig(cfg HookConfig) {
+	hookConfigMu.Lock()
+	hookConfig = cfg
+	hookConfigMu.Unlock()
+}
+
+// runHook invokes the configured post-set command, if enabled, passing file
+// as $1 and meta as environment variables. When Timeout is set, a hanging
+// script is killed after it so it can't block the fetcher; a zero Timeout
+// lets the script run until it exits.
+func runHook(file string, meta HookMeta) {
+	hookConfigMu.RLock()
+	cfg := hookConfig
+	hookConfigMu.RUnlock()
+
+	if !cfg.Enabled || cfg.Command == "" {
+		return
+	}
+
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", cfg.Command, "sh", file)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("WALLPAPER_SATELLITE=%s", meta.Satellite),
+		fmt.Sprintf("WALLPAPER_TIMESTAMP=%s", meta.Timestamp.Format(time.RFC3339)),
+		fmt.Sprintf("WALLPAPER_RESOLUTION=%s", meta.Resolution),
+	)
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("wallpaper: post-set hook failed: %v", err)
+	}
+}
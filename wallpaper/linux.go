@@ -0,0 +1,12 @@
+//go:build linux
+
+package wallpaper
+
+import (
+	"earth-wallpaper/wallpaper/linux"
+	"earth-wallpaper/wallpaper/modes"
+)
+
+func setFromFile(file string, mode modes.FillStyle) error {
+	return linux.SetWallpaper(file, mode)
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package linux
+
+import "earth-wallpaper/wallpaper/modes"
+
+// getGNOMEString maps a FillStyle to the picture-options string expected by
+// GNOME's dconf schema, which Cinnamon, Deepin, and MATE all reuse.
+func getGNOMEString(mode modes.FillStyle) string {
+	switch mode {
+	case modes.FILL_CENTER:
+		return "centered"
+	case modes.FILL_STRETCH:
+		return "stretched"
+	case modes.FILL_SCALE:
+		return "scaled"
+	case modes.FILL_ZOOM:
+		return "zoom"
+	case modes.FILL_SPAN:
+		return "spanned"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package linux
+
+import (
+	"os/exec"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetGNOME applies file as the wallpaper on plain GNOME via gsettings,
+// setting both the light and dark picture-uri keys so it sticks regardless
+// of the active color scheme.
+func SetGNOME(file string, mode modes.FillStyle) error {
+	err := exec.Command("gsettings", "set", "org.gnome.desktop.background",
+		"picture-options", getGNOMEString(mode)).Run()
+	if err != nil {
+		return err
+	}
+
+	err = exec.Command("gsettings", "set", "org.gnome.desktop.background",
+		"picture-uri", "file://"+file).Run()
+	if err != nil {
+		return err
+	}
+
+	return exec.Command("gsettings", "set", "org.gnome.desktop.background",
+		"picture-uri-dark", "file://"+file).Run()
+}
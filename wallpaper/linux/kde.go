@@ -0,0 +1,29 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"os/exec"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetKDE applies file as the wallpaper on KDE Plasma by asking plasmashell
+// to evaluate a small script through its D-Bus interface; Plasma has no
+// simple config key for this like the GNOME-derived desktops do. mode is
+// ignored: the image wallpaper plugin always fills the screen.
+func SetKDE(file string, mode modes.FillStyle) error {
+	script := fmt.Sprintf(`
+var allDesktops = desktops();
+for (i = 0; i < allDesktops.length; i++) {
+    d = allDesktops[i];
+    d.wallpaperPlugin = "org.kde.image";
+    d.currentConfigGroup = Array("Wallpaper", "org.kde.image", "General");
+    d.writeConfig("Image", "file://%s");
+}
+`, file)
+
+	return exec.Command("qdbus", "org.kde.plasmashell", "/PlasmaShell",
+		"org.kde.PlasmaShell.evaluateScript", script).Run()
+}
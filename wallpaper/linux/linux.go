@@ -0,0 +1,71 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetWallpaper detects the running desktop environment and applies file as
+// the wallpaper through the matching backend. Detection can be overridden
+// with the EARTH_WALLPAPER_DESKTOP environment variable (one of: cinnamon,
+// deepin, mate, gnome, kde, xfce, lxqt, wayland) for setups it gets wrong.
+func SetWallpaper(file string, mode modes.FillStyle) error {
+	target := strings.ToLower(os.Getenv("EARTH_WALLPAPER_DESKTOP"))
+	if target == "" {
+		target = detectDesktop()
+	}
+
+	switch target {
+	case "cinnamon":
+		return SetCinnamon(file, mode)
+	case "deepin":
+		return SetDeepin(file, mode)
+	case "mate":
+		return SetMate(file, mode)
+	case "kde", "plasma":
+		return SetKDE(file, mode)
+	case "xfce":
+		return SetXFCE(file, mode)
+	case "lxqt":
+		return SetLXQt(file, mode)
+	case "gnome":
+		return SetGNOME(file, mode)
+	case "wayland":
+		return SetWayland(file, mode)
+	default:
+		return fmt.Errorf("linux: unsupported or undetected desktop environment %q (set EARTH_WALLPAPER_DESKTOP to override)", target)
+	}
+}
+
+// detectDesktop guesses the desktop environment from XDG_CURRENT_DESKTOP,
+// falling back to a Wayland compositor check when no known desktop matches.
+func detectDesktop() string {
+	desktop := strings.ToLower(os.Getenv("XDG_CURRENT_DESKTOP"))
+
+	switch {
+	case strings.Contains(desktop, "cinnamon"):
+		return "cinnamon"
+	case strings.Contains(desktop, "deepin"):
+		return "deepin"
+	case strings.Contains(desktop, "mate"):
+		return "mate"
+	case strings.Contains(desktop, "kde"):
+		return "kde"
+	case strings.Contains(desktop, "xfce"):
+		return "xfce"
+	case strings.Contains(desktop, "lxqt"):
+		return "lxqt"
+	case strings.Contains(desktop, "gnome"):
+		return "gnome"
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return "wayland"
+	}
+	return ""
+}
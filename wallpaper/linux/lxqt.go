@@ -0,0 +1,15 @@
+//go:build linux
+
+package linux
+
+import (
+	"os/exec"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetLXQt applies file as the wallpaper on LXQt via pcmanfm-qt. mode is
+// ignored: pcmanfm-qt has no CLI flag to choose the fill style.
+func SetLXQt(file string, mode modes.FillStyle) error {
+	return exec.Command("pcmanfm-qt", "--set-wallpaper", file).Run()
+}
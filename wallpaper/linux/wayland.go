@@ -0,0 +1,26 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"os/exec"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetWayland applies file as the wallpaper under a Wayland compositor, using
+// whichever wallpaper tool is available. mode is ignored: both tools always
+// fill the screen.
+func SetWayland(file string, mode modes.FillStyle) error {
+	if _, err := exec.LookPath("swww"); err == nil {
+		return exec.Command("swww", "img", file).Run()
+	}
+	if _, err := exec.LookPath("hyprctl"); err == nil {
+		return exec.Command("hyprctl", "hyprpaper", "wallpaper", fmt.Sprintf(",%s", file)).Run()
+	}
+	if _, err := exec.LookPath("swaybg"); err == nil {
+		return fmt.Errorf("linux: swaybg can't change the wallpaper at runtime; install swww or hyprpaper instead")
+	}
+	return fmt.Errorf("linux: no supported Wayland wallpaper tool found (swww, hyprctl, swaybg)")
+}
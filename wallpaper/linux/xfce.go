@@ -0,0 +1,42 @@
+//go:build linux
+
+package linux
+
+import (
+	"os/exec"
+
+	"earth-wallpaper/wallpaper/modes"
+)
+
+// SetXFCE applies file as the wallpaper on XFCE via xfconf-query, targeting
+// the default screen0/monitor0/workspace0 property path.
+func SetXFCE(file string, mode modes.FillStyle) error {
+	err := exec.Command("xfconf-query", "-c", "xfce4-desktop",
+		"-p", "/backdrop/screen0/monitor0/workspace0/image-style",
+		"-s", xfceImageStyle(mode)).Run()
+	if err != nil {
+		return err
+	}
+
+	return exec.Command("xfconf-query", "-c", "xfce4-desktop",
+		"-p", "/backdrop/screen0/monitor0/workspace0/last-image",
+		"-s", file).Run()
+}
+
+// xfceImageStyle maps a FillStyle to XFCE's image-style property values.
+func xfceImageStyle(mode modes.FillStyle) string {
+	switch mode {
+	case modes.FILL_CENTER:
+		return "1"
+	case modes.FILL_STRETCH:
+		return "3"
+	case modes.FILL_SCALE:
+		return "4"
+	case modes.FILL_ZOOM:
+		return "5"
+	case modes.FILL_SPAN:
+		return "6"
+	default:
+		return "0"
+	}
+}
@@ -0,0 +1,16 @@
+// Package modes defines how a wallpaper image is fit to the screen.
+package modes
+
+// FillStyle describes how the desktop environment should scale and position
+// a wallpaper image relative to the screen.
+type FillStyle int
+
+const (
+	// FILL_ORIGINAL displays the image unscaled, anchored to the screen.
+	FILL_ORIGINAL FillStyle = iota
+	FILL_CENTER
+	FILL_STRETCH
+	FILL_SCALE
+	FILL_ZOOM
+	FILL_SPAN
+)
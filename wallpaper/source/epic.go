@@ -0,0 +1,81 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"net/http"
+	"time"
+)
+
+// EPIC downloads NASA's DSCOVR EPIC natural-color, full-disc Earth imagery.
+type EPIC struct {
+	// APIKey is a NASA API key. Empty falls back to the shared "DEMO_KEY",
+	// which is rate-limited.
+	APIKey string
+}
+
+func (e *EPIC) Label() string { return "EPIC (DSCOVR)" }
+
+func (e *EPIC) apiKey() string {
+	if e.APIKey == "" {
+		return "DEMO_KEY"
+	}
+	return e.APIKey
+}
+
+type epicImage struct {
+	Identifier string `json:"identifier"`
+	Image      string `json:"image"`
+	Date       string `json:"date"`
+}
+
+// ModifiedSince returns the id of the most recent natural-color image.
+func (e *EPIC) ModifiedSince(since time.Time) (string, error) {
+	url := fmt.Sprintf("https://epic.gsfc.nasa.gov/api/natural?api_key=%s", e.apiKey())
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("epic: unexpected status %d", resp.StatusCode)
+	}
+
+	var images []epicImage
+	if err := json.NewDecoder(resp.Body).Decode(&images); err != nil {
+		return "", err
+	}
+	if len(images) == 0 {
+		return "", fmt.Errorf("epic: no images available")
+	}
+
+	latest := images[len(images)-1]
+	t, err := time.Parse("2006-01-02 15:04:05", latest.Date)
+	if err != nil {
+		return "", fmt.Errorf("epic: parse date %q: %w", latest.Date, err)
+	}
+	return encodeID(t, latest.Image), nil
+}
+
+// Download fetches the archived PNG for id from EPIC's dated archive path.
+func (e *EPIC) Download(ctx context.Context, id string) (image.Image, error) {
+	t, name, err := decodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("epic: id %q has no image name", id)
+	}
+
+	url := fmt.Sprintf("https://epic.gsfc.nasa.gov/archive/natural/%04d/%02d/%02d/png/%s.png",
+		t.Year(), t.Month(), t.Day(), name)
+	return fetchImage(ctx, url)
+}
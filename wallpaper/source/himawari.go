@@ -0,0 +1,245 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"earth-wallpaper/cache"
+)
+
+const himawariTileSize = 550
+
+// httpClient is shared by all tile requests so they inherit one sane
+// timeout instead of hanging forever on a stalled connection.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+const (
+	tileMaxAttempts = 3
+	tileBaseDelay   = 500 * time.Millisecond
+)
+
+// Himawari downloads composite wallpapers stitched from the Himawari-8
+// satellite's public tile service.
+type Himawari struct {
+	// Resolution is the tile grid size (e.g. 4 means a 4x4 grid of tiles).
+	Resolution int
+
+	// Workers bounds how many tiles are downloaded concurrently. Zero uses
+	// runtime.NumCPU().
+	Workers int
+}
+
+func (h *Himawari) Label() string { return "Himawari 8" }
+
+type himawariLatest struct {
+	Date string `json:"date"`
+	File string `json:"file"`
+}
+
+// ModifiedSince fetches the satellite's latest.json and returns its date as
+// an id. since is unused: the endpoint has no "if newer than" query of its
+// own, so callers compare the returned id themselves.
+func (h *Himawari) ModifiedSince(since time.Time) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://jh170034-1.kudpc.kyoto-u.ac.jp/himawari/img/D531106/latest.json", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data himawariLatest
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", err
+	}
+
+	t, err := time.Parse("2006-01-02 15:04:05", data.Date)
+	if err != nil {
+		return "", fmt.Errorf("himawari: parse date %q: %w", data.Date, err)
+	}
+	return encodeID(t, ""), nil
+}
+
+// tilesFailedError reports that some tiles in a composite couldn't be
+// downloaded after retries; the composite is still returned, with blank
+// placeholders standing in for the failed tiles.
+type tilesFailedError struct {
+	failed, total int
+}
+
+func (e *tilesFailedError) Error() string {
+	return fmt.Sprintf("%d/%d tiles failed", e.failed, e.total)
+}
+
+// Download fetches every tile for id's timestamp through a bounded worker
+// pool and stitches them into one composite image, at the resolution
+// configured on h. If any tile fails every retry, Download still returns
+// the composite (with a blank placeholder for that tile) alongside a
+// *tilesFailedError so callers can surface "N/M tiles failed" instead of
+// silently shipping a corrupted wallpaper.
+func (h *Himawari) Download(ctx context.Context, id string) (image.Image, error) {
+	t, _, err := decodeID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	gridSize := h.Resolution
+	if gridSize <= 0 {
+		gridSize = 4
+	}
+	workers := h.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type tileJob struct{ x, y int }
+	type tileResult struct {
+		x, y int
+		img  image.Image
+	}
+
+	total := gridSize * gridSize
+	jobs := make(chan tileJob, total)
+	results := make(chan tileResult, total)
+	var failed int32
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				img, ok := downloadTileWithRetry(ctx, gridSize, job.x, job.y, t)
+				if !ok {
+					atomic.AddInt32(&failed, 1)
+				}
+				results <- tileResult{x: job.x, y: job.y, img: img}
+			}
+		}()
+	}
+
+	for i := 0; i < gridSize; i++ {
+		for j := 0; j < gridSize; j++ {
+			jobs <- tileJob{x: i, y: j}
+		}
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	canvas := image.NewRGBA(image.Rect(0, 0, gridSize*himawariTileSize, gridSize*himawariTileSize))
+	for res := range results {
+		dest := image.Rect(res.x*himawariTileSize, res.y*himawariTileSize, (res.x+1)*himawariTileSize, (res.y+1)*himawariTileSize)
+		draw.Draw(canvas, dest, res.img, image.Point{}, draw.Src)
+	}
+
+	if failed > 0 {
+		return canvas, &tilesFailedError{failed: int(failed), total: total}
+	}
+	return canvas, nil
+}
+
+// downloadTileWithRetry fetches a single tile, checking the disk cache
+// first. On a cache miss it makes up to tileMaxAttempts attempts, with
+// exponential backoff and jitter between them, before giving up and
+// returning a blank placeholder with ok=false.
+func downloadTileWithRetry(ctx context.Context, resolution, i, j int, t time.Time) (img image.Image, ok bool) {
+	tilePath, pathErr := cache.TilePath(resolution, t, i, j)
+	if pathErr == nil {
+		if f, err := os.Open(tilePath); err == nil {
+			img, err := png.Decode(f)
+			f.Close()
+			if err == nil {
+				return img, true
+			}
+			log.Printf("downloadTile: cached tile %s unreadable, refetching: %v", tilePath, err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < tileMaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := tileBaseDelay << uint(attempt-1)
+			delay += time.Duration(rand.Int63n(int64(delay)))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return image.NewRGBA(image.Rect(0, 0, himawariTileSize, himawariTileSize)), false
+			}
+		}
+
+		tileImg, data, err := fetchTile(ctx, resolution, i, j, t)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if pathErr == nil {
+			if err := os.WriteFile(tilePath, data, 0o644); err != nil {
+				log.Printf("downloadTile: failed to cache tile %s: %v", tilePath, err)
+			}
+		}
+		return tileImg, true
+	}
+
+	log.Printf("downloadTile: giving up on tile %d,%d after %d attempts: %v", i, j, tileMaxAttempts, lastErr)
+	return image.NewRGBA(image.Rect(0, 0, himawariTileSize, himawariTileSize)), false
+}
+
+// fetchTile makes a single attempt at downloading and decoding one tile,
+// returning both the decoded image and its raw bytes so the caller can
+// cache them without re-encoding.
+func fetchTile(ctx context.Context, resolution, i, j int, t time.Time) (image.Image, []byte, error) {
+	timeStr := fmt.Sprintf("%02d%02d%02d", t.Hour(), t.Minute(), t.Second())
+	url := fmt.Sprintf("https://anzu.shinshu-u.ac.jp/himawari/img/D531106/%dd/550/%04d/%02d/%02d/%s_%d_%d.png",
+		resolution, t.Year(), t.Month(), t.Day(), timeStr, i, j)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("non-200 status %d for %s", resp.StatusCode, url)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return nil, nil, fmt.Errorf("read body for %s: %w", url, err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode png for %s: %w", url, err)
+	}
+	return img, buf.Bytes(), nil
+}
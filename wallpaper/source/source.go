@@ -0,0 +1,92 @@
+// Package source defines the pluggable satellite/space imagery backends used
+// to build the desktop wallpaper. main.go only ever talks to the active
+// Downloader through this interface, so adding a new imagery provider never
+// requires touching the fetch loop.
+package source
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Downloader is implemented by each imagery backend (Himawari, EPIC, APOD, ...).
+type Downloader interface {
+	// Label is the name shown in the systray source submenu.
+	Label() string
+
+	// ModifiedSince reports the id of the most recent image the source has
+	// available. The id is opaque to callers except that it always embeds an
+	// RFC3339 timestamp recoverable with Time, so callers can tell whether it
+	// is newer than an id they already have.
+	ModifiedSince(since time.Time) (id string, err error)
+
+	// Download fetches and assembles the wallpaper image identified by id.
+	Download(ctx context.Context, id string) (image.Image, error)
+}
+
+// encodeID packs a timestamp and source-specific payload into the opaque id
+// string returned from ModifiedSince.
+func encodeID(t time.Time, payload string) string {
+	if payload == "" {
+		return t.Format(time.RFC3339)
+	}
+	return t.Format(time.RFC3339) + "#" + payload
+}
+
+// decodeID is the inverse of encodeID.
+func decodeID(id string) (time.Time, string, error) {
+	head, payload, _ := strings.Cut(id, "#")
+	t, err := time.Parse(time.RFC3339, head)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("source: parse id %q: %w", id, err)
+	}
+	return t, payload, nil
+}
+
+// Time extracts the timestamp embedded in an id returned by any Downloader's
+// ModifiedSince, so callers can compare/display it without caring which
+// source produced it.
+func Time(id string) (time.Time, error) {
+	t, _, err := decodeID(id)
+	return t, err
+}
+
+// WithOffset returns an id shifted by offset, preserving any source-specific
+// payload embedded in id. Used to honor the configured daylight offset and
+// to step backward through time for timelapse playback. Sources whose
+// payload, not the timestamp, picks the image (e.g. APOD's per-day date, or
+// EPIC's image name) may simply redownload the same image for small offsets.
+func WithOffset(id string, offset time.Duration) (string, error) {
+	t, payload, err := decodeID(id)
+	if err != nil {
+		return "", err
+	}
+	return encodeID(t.Add(offset), payload), nil
+}
+
+// fetchImage GETs url and decodes whatever image format it comes back as.
+// Shared by the sources that fetch a single already-composed image (EPIC,
+// APOD); Himawari stitches tiles itself and doesn't need this.
+func fetchImage(ctx context.Context, url string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source: unexpected status %d for %s", resp.StatusCode, url)
+	}
+
+	img, _, err := image.Decode(resp.Body)
+	return img, err
+}
@@ -0,0 +1,32 @@
+// Package wallpaper sets the desktop wallpaper. The actual mechanism is
+// provided per-OS by a build-tagged setFromFile (see darwin.go, linux.go);
+// this file holds the fill-mode state shared across calls and the optional
+// post-set hook.
+package wallpaper
+
+import "earth-wallpaper/wallpaper/modes"
+
+var currentMode = modes.FILL_ORIGINAL
+
+// SetMode changes the fill style used by subsequent calls to SetWallpaper.
+func SetMode(mode modes.FillStyle) {
+	currentMode = mode
+}
+
+// SetWallpaper applies file as the desktop wallpaper using the current fill
+// style. It runs the post-set hook with empty metadata; use
+// SetWallpaperWithMeta when metadata is available.
+func SetWallpaper(file string) error {
+	return SetWallpaperWithMeta(file, HookMeta{})
+}
+
+// SetWallpaperWithMeta applies file as the desktop wallpaper using the
+// current fill style, then runs the configured post-set hook (see
+// SetHookConfig), passing meta through as environment variables.
+func SetWallpaperWithMeta(file string, meta HookMeta) error {
+	if err := setFromFile(file, currentMode); err != nil {
+		return err
+	}
+	runHook(file, meta)
+	return nil
+}